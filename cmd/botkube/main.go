@@ -0,0 +1,131 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/infracloudio/botkube/pkg/bot"
+	"github.com/infracloudio/botkube/pkg/config"
+	"github.com/infracloudio/botkube/pkg/execute"
+	"github.com/infracloudio/botkube/pkg/filterengine"
+	"github.com/infracloudio/botkube/pkg/logging"
+)
+
+// botkubeNamespace is the namespace BotKube runs in, used to persist filter
+// enabled/disabled state to a ConfigMap.
+const botkubeNamespace = "botkube"
+
+// filterStateConfigMap is the ConfigMap filter enabled/disabled state is persisted to.
+const filterStateConfigMap = "botkube-filters"
+
+func main() {
+	kubeconfig := flag.String("kubeconfig", os.Getenv("KUBECONFIG"), "path to the kubeconfig file; uses in-cluster config when unset")
+	flag.Parse()
+
+	conf, err := config.New()
+	if err != nil {
+		logging.Logger.Fatalf("Failed to load configuration. %s", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-sig
+		logging.Logger.Info("Shutdown signal received, stopping bots")
+		cancel()
+	}()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		logging.Logger.Fatalf("Failed to initialize logger. %s", err.Error())
+	}
+	defer logger.Sync() // nolint:errcheck
+
+	// BuildConfigFromFlags falls back to in-cluster config when kubeconfig is
+	// empty, so botkube still runs without a flag/KUBECONFIG set when
+	// deployed inside a cluster, while also working against a local cluster
+	// for development or the Discord e2e workflow.
+	restConfig, err := clientcmd.BuildConfigFromFlags("", *kubeconfig)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to build kube config. %s", err.Error())
+	}
+	kubeClient, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		logging.Logger.Fatalf("Failed to create kube client. %s", err.Error())
+	}
+
+	filterEngine := filterengine.New(
+		kubeClient,
+		logger.Named("filterengine"),
+		filterengine.NewConfigMapPersister(kubeClient, botkubeNamespace, filterStateConfigMap),
+	)
+	// No filters are registered yet. This is the extension point production
+	// filters are meant to be added at, e.g.:
+	//   filterEngine.Register(filters.NewNamespaceChecker())
+	// Until then, "filters list" legitimately reports an empty table and
+	// "filters enable/disable" has nothing to toggle.
+
+	bots := newBots(conf, logger, filterEngine)
+
+	g, ctx := errgroup.WithContext(ctx)
+	for _, b := range bots {
+		b := b
+		g.Go(func() error {
+			return b.Start(ctx)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		logging.Logger.Fatalf("Bot terminated with error. %s", err.Error())
+	}
+}
+
+// newExecutor adapts execute.NewDefaultExecutor to the execute.Factory
+// signature expected by the bot backends.
+func newExecutor(msg string, allowKubectl, restrictAccess bool, clusterName string, isAuthChannel bool) execute.Executor {
+	return execute.NewDefaultExecutor(msg, allowKubectl, restrictAccess, clusterName, isAuthChannel)
+}
+
+// newBots builds the list of chat backends enabled in the configuration,
+// each with its own logger scoped to the backend's name and sharing the
+// single filter engine instance.
+func newBots(conf *config.Config, logger *zap.Logger, filterEngine filterengine.Engine) []bot.Bot {
+	var bots []bot.Bot
+	if conf.Communications.Teams.Enabled {
+		bots = append(bots, bot.NewTeamsBot(conf, logger.Named("teams"), newExecutor, filterEngine))
+	}
+	if conf.Communications.Discord.Enabled {
+		bots = append(bots, bot.NewDiscordBot(conf, logger.Named("discord"), newExecutor, filterEngine))
+	}
+	return bots
+}
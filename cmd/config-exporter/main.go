@@ -0,0 +1,201 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Command config-exporter connects to an installed BotKube's Kubernetes
+// namespace, reads its ConfigMap and Secret, migrates the result to the
+// current config schema, redacts sensitive fields, and prints the resulting
+// YAML so it can be reviewed and re-imported into a newer BotKube release.
+//
+// Usage:
+//
+//	config-exporter --namespace botkube --configmap botkube-config --secret botkube-communication-secrets
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/infracloudio/botkube/pkg/config"
+	"github.com/infracloudio/botkube/pkg/config/migrate"
+)
+
+// redactedFields lists the dot-separated paths, within the merged config
+// document, of values that must never be printed as-is.
+var redactedFields = [][]string{
+	{"communications", "teams", "botpassword"},
+	{"communications", "discord", "token"},
+}
+
+const redactedPlaceholder = "<REDACTED>"
+
+func main() {
+	var (
+		kubeconfig string
+		namespace  string
+		configMap  string
+		secret     string
+	)
+	flag.StringVar(&kubeconfig, "kubeconfig", os.Getenv("KUBECONFIG"), "path to the kubeconfig file")
+	flag.StringVar(&namespace, "namespace", "botkube", "namespace the BotKube instance is installed in")
+	flag.StringVar(&configMap, "configmap", "botkube-config", "name of the BotKube ConfigMap")
+	flag.StringVar(&secret, "secret", "botkube-communication-secrets", "name of the BotKube communication Secret")
+	flag.Parse()
+
+	if err := run(kubeconfig, namespace, configMap, secret); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(kubeconfig, namespace, configMapName, secretName string) error {
+	restConfig, err := clientcmd.BuildConfigFromFlags("", kubeconfig)
+	if err != nil {
+		return fmt.Errorf("failed to build kube config: %w", err)
+	}
+	clientset, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return fmt.Errorf("failed to create kube client: %w", err)
+	}
+
+	ctx := context.Background()
+	cm, err := clientset.CoreV1().ConfigMaps(namespace).Get(ctx, configMapName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to read configmap %s/%s: %w", namespace, configMapName, err)
+	}
+	sec, err := clientset.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to read secret %s/%s: %w", namespace, secretName, err)
+	}
+
+	doc, err := mergeConfigDocument(cm.Data, sec.Data)
+	if err != nil {
+		return err
+	}
+
+	if err := migrate.Run(doc, config.CurrentConfigVersion); err != nil {
+		return fmt.Errorf("failed to migrate config: %w", err)
+	}
+
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal migrated config: %w", err)
+	}
+
+	// Validate the migrated config against the current schema before
+	// redacting, so unknown keys fail loudly rather than being dropped
+	// silently by a lenient unmarshal.
+	cfg, err := config.ParseStrict(out)
+	if err != nil {
+		return fmt.Errorf("migrated config does not match current schema: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+
+	redact(doc, redactedFields)
+	redacted, err := yaml.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal redacted config: %w", err)
+	}
+
+	fmt.Print(string(redacted))
+	return nil
+}
+
+// mergeConfigDocument combines the ConfigMap's config.yaml with any
+// overrides held in the Secret (keyed the same way) into a single raw YAML
+// document, so migrate.Run can operate on it as one tree.
+func mergeConfigDocument(configMapData, secretData map[string][]byte) (map[string]interface{}, error) {
+	doc := map[string]interface{}{}
+	if data, ok := configMapData["config.yaml"]; ok {
+		if err := yaml.Unmarshal([]byte(data), &doc); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal configmap data: %w", err)
+		}
+	}
+	for key, value := range secretData {
+		setNested(doc, key, string(value))
+	}
+	return doc, nil
+}
+
+// setNested writes value at the dotted path key (e.g. "communications.teams.botpassword")
+// within doc, creating intermediate maps as needed. It walks through
+// migrate.SubMap rather than asserting map[string]interface{} directly, since
+// yaml.Unmarshal (which built doc from the ConfigMap) decodes nested mappings
+// as map[interface{}]interface{}; asserting the wrong shape would look like a
+// missing intermediate map and silently replace it, discarding every other
+// field already read from the ConfigMap.
+func setNested(doc map[string]interface{}, key, value string) {
+	parts := splitPath(key)
+	m := doc
+	for _, p := range parts[:len(parts)-1] {
+		next := migrate.SubMap(m, p)
+		if next == nil {
+			next = map[string]interface{}{}
+			m[p] = next
+		}
+		m = next
+	}
+	m[parts[len(parts)-1]] = value
+}
+
+func splitPath(key string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(key); i++ {
+		if key[i] == '.' {
+			parts = append(parts, key[start:i])
+			start = i + 1
+		}
+	}
+	parts = append(parts, key[start:])
+	return parts
+}
+
+// redact walks doc through migrate.SubMap for the same reason setNested
+// does: migrate.Run only converts communications, communications.teams and
+// settings, so sibling subtrees
+// like communications.discord are still the raw map[interface{}]interface{}
+// yaml.Unmarshal produced, and a plain map[string]interface{} assertion
+// would silently skip redacting them.
+func redact(doc map[string]interface{}, fields [][]string) {
+	for _, path := range fields {
+		m := doc
+		for _, p := range path[:len(path)-1] {
+			m = migrate.SubMap(m, p)
+			if m == nil {
+				break
+			}
+		}
+		if m == nil {
+			continue
+		}
+		last := path[len(path)-1]
+		if _, ok := m[last]; ok {
+			m[last] = redactedPlaceholder
+		}
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/infracloudio/botkube/pkg/config/migrate"
+)
+
+// TestMergeSetNestedRedactRoundTrip guards against setNested and redact
+// asserting map[string]interface{} while walking a document built by a real
+// yaml.Unmarshal, which decodes nested mappings as
+// map[interface{}]interface{}. A wrong assertion in setNested would
+// silently discard every ConfigMap field under the first path segment it
+// touches; a wrong assertion in redact would silently skip redacting a
+// subtree migrate.Run never visited, like communications.discord.
+func TestMergeSetNestedRedactRoundTrip(t *testing.T) {
+	configMapData := map[string][]byte{
+		"config.yaml": []byte(`
+communications:
+  teams:
+    enabled: true
+    appid: teams-app-id
+    messagepath: /bk
+  discord:
+    enabled: true
+    botid: discord-bot-id
+    guildid: discord-guild-id
+settings:
+  clustername: prod
+`),
+	}
+	secretData := map[string][]byte{
+		"communications.teams.botpassword": []byte("teams-secret"),
+		"communications.discord.token":     []byte("discord-secret"),
+	}
+
+	doc, err := mergeConfigDocument(configMapData, secretData)
+	if err != nil {
+		t.Fatalf("mergeConfigDocument returned error: %v", err)
+	}
+
+	teams := migrate.SubMap(migrate.SubMap(doc, "communications"), "teams")
+	if teams == nil {
+		t.Fatal("communications.teams missing after merge")
+	}
+	if teams["appid"] != "teams-app-id" {
+		t.Errorf("communications.teams.appid = %v, want teams-app-id (merging the secret must not discard sibling ConfigMap fields)", teams["appid"])
+	}
+	if teams["messagepath"] != "/bk" {
+		t.Errorf("communications.teams.messagepath = %v, want /bk", teams["messagepath"])
+	}
+	if teams["botpassword"] != "teams-secret" {
+		t.Errorf("communications.teams.botpassword = %v, want teams-secret", teams["botpassword"])
+	}
+
+	discord := migrate.SubMap(migrate.SubMap(doc, "communications"), "discord")
+	if discord == nil {
+		t.Fatal("communications.discord missing after merge")
+	}
+	if discord["botid"] != "discord-bot-id" {
+		t.Errorf("communications.discord.botid = %v, want discord-bot-id", discord["botid"])
+	}
+	if discord["token"] != "discord-secret" {
+		t.Errorf("communications.discord.token = %v, want discord-secret", discord["token"])
+	}
+
+	redact(doc, redactedFields)
+
+	teams = migrate.SubMap(migrate.SubMap(doc, "communications"), "teams")
+	if teams["botpassword"] != redactedPlaceholder {
+		t.Errorf("communications.teams.botpassword = %v, want redacted", teams["botpassword"])
+	}
+	discord = migrate.SubMap(migrate.SubMap(doc, "communications"), "discord")
+	if discord["token"] != redactedPlaceholder {
+		t.Errorf("communications.discord.token = %v, want redacted", discord["token"])
+	}
+	if discord["botid"] != "discord-bot-id" {
+		t.Errorf("communications.discord.botid = %v, want untouched discord-bot-id", discord["botid"])
+	}
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package bot
+
+import (
+	"context"
+
+	"github.com/infracloudio/botkube/pkg/events"
+)
+
+// Bot is the interface implemented by every chat backend BotKube can start.
+// Start must block until ctx is cancelled and then return after releasing
+// any resources it owns, so callers can run a group of bots together and
+// bring them all down on a single shutdown signal.
+type Bot interface {
+	// Start runs the bot backend until ctx is cancelled, returning any
+	// error encountered. A nil error means ctx was cancelled and the
+	// backend shut down cleanly.
+	Start(ctx context.Context) error
+	// SendEvent sends a notification event to the backend's configured channel.
+	SendEvent(ctx context.Context, event events.Event) error
+	// SendMessage sends a plain text message to the backend's configured channel.
+	SendMessage(ctx context.Context, msg string) error
+}
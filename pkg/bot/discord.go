@@ -0,0 +1,183 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package bot
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"go.uber.org/zap"
+
+	"github.com/infracloudio/botkube/pkg/config"
+	"github.com/infracloudio/botkube/pkg/events"
+	"github.com/infracloudio/botkube/pkg/execute"
+	"github.com/infracloudio/botkube/pkg/filterengine"
+)
+
+// discordMaxMessageSize is Discord's hard limit on a single message's
+// content length. Responses over this size are uploaded as a file
+// attachment instead of being sent inline.
+const discordMaxMessageSize = 2000
+
+var _ Bot = (*Discord)(nil)
+
+// Discord contains credentials to start Discord backend server
+type Discord struct {
+	Token           string
+	BotID           string
+	GuildID         string
+	ChannelID       string
+	AllowKubectl    bool
+	RestrictAccess  bool
+	ClusterName     string
+	NotifType       config.NotifType
+	logger          *zap.SugaredLogger
+	executorFactory execute.Factory
+	filterEngine    filterengine.Engine
+
+	session *discordgo.Session
+}
+
+// NewDiscordBot returns Discord instance with the given logger scoped to it.
+func NewDiscordBot(c *config.Config, logger *zap.Logger, executorFactory execute.Factory, filterEngine filterengine.Engine) *Discord {
+	return &Discord{
+		Token:           c.Communications.Discord.Token,
+		BotID:           c.Communications.Discord.BotID,
+		GuildID:         c.Communications.Discord.GuildID,
+		ChannelID:       c.Communications.Discord.ChannelID,
+		AllowKubectl:    c.Settings.AllowKubectl,
+		RestrictAccess:  c.Settings.RestrictAccess,
+		ClusterName:     c.Settings.ClusterName,
+		logger:          logger.Sugar(),
+		executorFactory: executorFactory,
+		filterEngine:    filterEngine,
+	}
+}
+
+// Start opens a Discord gateway session and handles messages until ctx is
+// cancelled, at which point the session is closed and Start returns.
+func (d *Discord) Start(ctx context.Context) error {
+	session, err := discordgo.New("Bot " + d.Token)
+	if err != nil {
+		return fmt.Errorf("failed to create discord session: %w", err)
+	}
+	session.AddHandler(d.onMessage)
+
+	if err := session.Open(); err != nil {
+		return fmt.Errorf("failed to open discord session: %w", err)
+	}
+	d.session = session
+	d.logger.Info("Started Discord bot")
+
+	<-ctx.Done()
+	d.logger.Info("Shutting down Discord bot")
+	return session.Close()
+}
+
+func (d *Discord) onMessage(s *discordgo.Session, m *discordgo.MessageCreate) {
+	if m.Author.ID == d.BotID {
+		return
+	}
+	if !d.isMentioned(m.Mentions) {
+		return
+	}
+
+	msg := strings.TrimSpace(strings.NewReplacer(fmt.Sprintf("<@%s>", d.BotID), "", fmt.Sprintf("<@!%s>", d.BotID), "").Replace(m.Content))
+	e := d.executorFactory(msg, d.AllowKubectl, d.RestrictAccess, d.ClusterName, true)
+	out := e.Execute()
+
+	if err := d.send(m.ChannelID, out); err != nil {
+		d.logger.Errorf("Failed to send message. %s", err.Error())
+	}
+}
+
+func (d *Discord) isMentioned(mentions []*discordgo.User) bool {
+	for _, u := range mentions {
+		if u.ID == d.BotID {
+			return true
+		}
+	}
+	return false
+}
+
+// send writes resp to channelID, chunking it into a file attachment when it
+// exceeds Discord's 2000 character message limit rather than truncating it.
+func (d *Discord) send(channelID, resp string) error {
+	if len(resp) == 0 {
+		return nil
+	}
+	wrapped, fitsInline := wrapForDiscord(resp)
+	if fitsInline {
+		_, err := d.session.ChannelMessageSend(channelID, wrapped)
+		return err
+	}
+
+	_, err := d.session.ChannelFileSend(channelID, "response.txt", bytes.NewBufferString(resp))
+	return err
+}
+
+// wrapForDiscord code-block-wraps resp and reports whether the wrapped
+// string, not the raw resp, fits under Discord's message size limit.
+func wrapForDiscord(resp string) (wrapped string, fitsInline bool) {
+	wrapped = fmt.Sprintf("```%s```", resp)
+	return wrapped, len(wrapped) < discordMaxMessageSize
+}
+
+// SendEvent sends event notification to Discord
+func (d *Discord) SendEvent(ctx context.Context, event events.Event) error {
+	filtered, err := d.filterEngine.Run(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to run filters on event: %w", err)
+	}
+	event, ok := filtered.(events.Event)
+	if !ok {
+		return fmt.Errorf("filter chain returned unexpected type %T", filtered)
+	}
+
+	msg := formatDiscordMessage(event, d.NotifType)
+	if err := d.send(d.ChannelID, msg); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	d.logger.Debugf("Event successfully sent to Discord >> %+v", event)
+	return nil
+}
+
+// formatDiscordMessage renders event as Discord-ready text, mirroring
+// formatTeamsMessage's two rendering modes: config.ShortNotify sends a
+// compact one-line summary, anything else sends the event's full detail.
+func formatDiscordMessage(event events.Event, notifType config.NotifType) string {
+	if notifType == config.ShortNotify {
+		return fmt.Sprintf("%v", event)
+	}
+	return fmt.Sprintf("%+v", event)
+}
+
+// SendMessage sends a plain text message to Discord
+func (d *Discord) SendMessage(ctx context.Context, msg string) error {
+	if d.ChannelID == "" {
+		d.logger.Info("Skipping SendMessage since channel ID not set")
+		return nil
+	}
+	_, err := d.session.ChannelMessageSend(d.ChannelID, msg)
+	return err
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+func TestDiscordIsMentioned(t *testing.T) {
+	d := &Discord{BotID: "bot-1"}
+
+	type test struct {
+		name     string
+		mentions []*discordgo.User
+		expected bool
+	}
+
+	tests := []test{
+		{name: "mentioned", mentions: []*discordgo.User{{ID: "bot-1"}}, expected: true},
+		{name: "not mentioned", mentions: []*discordgo.User{{ID: "someone-else"}}, expected: false},
+		{name: "no mentions", mentions: nil, expected: false},
+	}
+
+	for _, ts := range tests {
+		got := d.isMentioned(ts.mentions)
+		if got != ts.expected {
+			t.Errorf("%s: isMentioned() = %v, want %v", ts.name, got, ts.expected)
+		}
+	}
+}
+
+// TestWrapForDiscord guards against checking resp's length before wrapping
+// it in the triple backticks send() adds: a response a few characters under
+// the limit becomes a wrapped string over it, and must be reported as such.
+func TestWrapForDiscord(t *testing.T) {
+	type test struct {
+		name           string
+		resp           string
+		expectedInline bool
+	}
+
+	tests := []test{
+		{name: "well under limit", resp: "short response", expectedInline: true},
+		{name: "just under limit pre-wrap, over post-wrap", resp: strings.Repeat("a", discordMaxMessageSize-3), expectedInline: false},
+		{name: "well over limit", resp: strings.Repeat("a", discordMaxMessageSize*2), expectedInline: false},
+	}
+
+	for _, ts := range tests {
+		wrapped, fitsInline := wrapForDiscord(ts.resp)
+		if fitsInline != ts.expectedInline {
+			t.Errorf("%s: fitsInline = %v, want %v", ts.name, fitsInline, ts.expectedInline)
+		}
+		if fitsInline && len(wrapped) >= discordMaxMessageSize {
+			t.Errorf("%s: wrapped length %d reported as fitting but is not under the limit", ts.name, len(wrapped))
+		}
+	}
+}
@@ -31,10 +31,11 @@ import (
 	"github.com/infracloudio/botkube/pkg/config"
 	"github.com/infracloudio/botkube/pkg/events"
 	"github.com/infracloudio/botkube/pkg/execute"
-	"github.com/infracloudio/botkube/pkg/logging"
+	"github.com/infracloudio/botkube/pkg/filterengine"
 	"github.com/infracloudio/msbotbuilder-go/core"
 	coreActivity "github.com/infracloudio/msbotbuilder-go/core/activity"
 	"github.com/infracloudio/msbotbuilder-go/schema"
+	"go.uber.org/zap"
 )
 
 const (
@@ -45,6 +46,10 @@ const (
 	convTypePersonal  = "personal"
 	channelSetCmd     = "set default channel"
 	maxMessageSize    = 15700
+
+	filtersListCmd    = "filters list"
+	filtersEnableCmd  = "filters enable"
+	filtersDisableCmd = "filters disable"
 )
 
 var _ Bot = (*Teams)(nil)
@@ -61,7 +66,10 @@ type Teams struct {
 	NotifType         config.NotifType
 	Adapter           core.Adapter
 	ProcessedConsents chan processedConsent
-	CleanupDone       chan bool
+	server            *http.Server
+	logger            *zap.SugaredLogger
+	executorFactory   execute.Factory
+	filterEngine      filterengine.Engine
 
 	ConversationRef *schema.ConversationReference
 }
@@ -75,25 +83,46 @@ type ConsentContext struct {
 	Command string
 }
 
-// NewTeamsBot returns Teams instance
-func NewTeamsBot(c *config.Config) *Teams {
-	logging.Logger.Infof("Config:: %+v", c.Communications.Teams)
+// NewTeamsBot returns Teams instance with the given logger scoped to it, so
+// that multiple bot instances don't share a single package-level logger.
+// executorFactory builds the CommandExecutor used to run incoming commands,
+// letting callers swap the execution backend without touching bot code.
+// filterEngine runs the per-instance filter chain over outgoing events and
+// backs the "filters list/enable/disable" commands.
+func NewTeamsBot(c *config.Config, logger *zap.Logger, executorFactory execute.Factory, filterEngine filterengine.Engine) *Teams {
+	sugar := logger.Sugar()
+	sugar.Infof("Config:: %+v", c.Communications.Teams)
+
+	messagePath := c.Communications.Teams.MessagePath
+	if messagePath == "" {
+		messagePath = defaultMsgPath
+	}
+	port := c.Communications.Teams.Port
+	if port == "" {
+		port = defaultPort
+	}
+
 	return &Teams{
 		AppID:             c.Communications.Teams.AppID,
-		AppPassword:       c.Communications.Teams.AppPassword,
+		AppPassword:       c.Communications.Teams.BotPassword,
 		NotifType:         c.Communications.Teams.NotifType,
-		MessagePath:       defaultMsgPath,
-		Port:              defaultPort,
+		MessagePath:       messagePath,
+		Port:              port,
 		AllowKubectl:      c.Settings.AllowKubectl,
 		RestrictAccess:    c.Settings.RestrictAccess,
 		ClusterName:       c.Settings.ClusterName,
 		ProcessedConsents: make(chan processedConsent, consentBufferSize),
-		CleanupDone:       make(chan bool),
+		logger:            sugar,
+		executorFactory:   executorFactory,
+		filterEngine:      filterEngine,
 	}
 }
 
-// Start MS Teams server to serve messages from Teams client
-func (t *Teams) Start() {
+// Start runs the MS Teams server to serve messages from the Teams client
+// until ctx is cancelled, at which point it shuts the server down and
+// returns. It satisfies the Bot interface so it can be run as part of an
+// errgroup.Group alongside the other backends.
+func (t *Teams) Start(ctx context.Context) error {
 	var err error
 	setting := core.AdapterSetting{
 		AppID:       t.AppID,
@@ -101,36 +130,62 @@ func (t *Teams) Start() {
 	}
 	t.Adapter, err = core.NewBotAdapter(setting)
 	if err != nil {
-		logging.Logger.Errorf("Failed Start teams bot. %+v", err)
-		return
+		return fmt.Errorf("failed to start teams bot: %w", err)
 	}
 	// Start consent cleanup
-	go t.cleanupConsents()
-	http.HandleFunc(t.MessagePath, t.processActivity)
-	logging.Logger.Infof("Started MS Teams server on port %s", defaultPort)
-	logging.Logger.Errorf("Error in MS Teams server. %v", http.ListenAndServe(fmt.Sprintf(":%s", t.Port), nil))
-	t.CleanupDone <- true
+	go t.cleanupConsents(ctx)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(t.MessagePath, t.processActivity)
+	t.server = &http.Server{
+		Addr:    fmt.Sprintf(":%s", t.Port),
+		Handler: mux,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		t.logger.Infof("Started MS Teams server on port %s", t.Port)
+		if err := t.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+			return
+		}
+		errChan <- nil
+	}()
+
+	select {
+	case <-ctx.Done():
+		t.logger.Infof("Shutting down MS Teams server")
+		if err := t.server.Shutdown(context.Background()); err != nil {
+			return fmt.Errorf("failed to shut down teams server: %w", err)
+		}
+		return nil
+	case err := <-errChan:
+		if err != nil {
+			return fmt.Errorf("error in MS Teams server: %w", err)
+		}
+		return nil
+	}
 }
 
-func (t *Teams) cleanupConsents() {
+func (t *Teams) cleanupConsents(ctx context.Context) {
 	for {
 		select {
 		case consent := <-t.ProcessedConsents:
 			fmt.Printf("Deleting activity %s\n", consent.ID)
-			if err := t.Adapter.DeleteActivity(context.Background(), consent.ID, consent.conversationRef); err != nil {
-				logging.Logger.Errorf("Failed to delete activity. %s", err.Error())
+			if err := t.Adapter.DeleteActivity(ctx, consent.ID, consent.conversationRef); err != nil {
+				t.logger.Errorf("Failed to delete activity. %s", err.Error())
 			}
-		case <-t.CleanupDone:
+		case <-ctx.Done():
 			return
 		}
 	}
 }
 
 func (t *Teams) processActivity(w http.ResponseWriter, req *http.Request) {
-	ctx := context.Background()
+	ctx := req.Context()
 	activity, err := t.Adapter.ParseRequest(ctx, req)
 	if err != nil {
-		logging.Logger.Errorf("Failed to parse Teams request. %s", err.Error())
+		t.logger.Errorf("Failed to parse Teams request. %s", err.Error())
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -138,9 +193,21 @@ func (t *Teams) processActivity(w http.ResponseWriter, req *http.Request) {
 	err = t.Adapter.ProcessActivity(ctx, activity, coreActivity.HandlerFuncs{
 		OnMessageFunc: func(turn *coreActivity.TurnContext) (schema.Activity, error) {
 			//actjson, _ := json.MarshalIndent(turn.Activity, "", "  ")
-			//logging.Logger.Debugf("Received activity: %s", actjson)
+			//t.logger.Debugf("Received activity: %s", actjson)
 			resp := t.processMessage(turn.Activity)
-			if len(resp) >= maxMessageSize {
+
+			if resp.Command != "" && isStructuredCommand(resp.Command) && len(resp.Text) < maxMessageSize {
+				attachments := []schema.Attachment{
+					{
+						ContentType: "application/vnd.microsoft.card.adaptive",
+						Content:     buildAdaptiveCard(t.ClusterName, resp.Command, resp.Output),
+					},
+				}
+				return turn.SendActivity(coreActivity.MsgOptionAttachments(attachments))
+			}
+
+			text := resp.Text
+			if len(text) >= maxMessageSize {
 				if turn.Activity.Conversation.ConversationType == convTypePersonal {
 					// send file upload request
 					attachments := []schema.Attachment{
@@ -149,7 +216,7 @@ func (t *Teams) processActivity(w http.ResponseWriter, req *http.Request) {
 							Name:        "response.txt",
 							Content: map[string]interface{}{
 								"description": turn.Activity.Text,
-								"sizeInBytes": len(resp),
+								"sizeInBytes": len(text),
 								"acceptContext": map[string]interface{}{
 									"command": activity.Text,
 								},
@@ -158,14 +225,18 @@ func (t *Teams) processActivity(w http.ResponseWriter, req *http.Request) {
 					}
 					return turn.SendActivity(coreActivity.MsgOptionAttachments(attachments))
 				}
-				resp = fmt.Sprintf("%s\n```\nCluster: %s\n%s", longRespNotice, t.ClusterName, resp[len(resp)-maxMessageSize:])
+				text = fmt.Sprintf("%s\n```\nCluster: %s\n%s", longRespNotice, t.ClusterName, text[len(text)-maxMessageSize:])
 			}
-			return turn.SendActivity(coreActivity.MsgOptionText(resp))
+			return turn.SendActivity(coreActivity.MsgOptionText(text))
 		},
 
 		// handle invoke events
 		// https://developer.microsoft.com/en-us/microsoft-teams/blogs/working-with-files-in-your-microsoft-teams-bot/
 		OnInvokeFunc: func(turn *coreActivity.TurnContext) (schema.Activity, error) {
+			if turn.Activity.Value["type"] == actionSubmitType {
+				return t.handleActionSubmit(turn)
+			}
+
 			t.pushProcessedConsent(turn.Activity.ReplyToID, coreActivity.GetCoversationReference(turn.Activity))
 			if err != nil {
 				return schema.Activity{}, fmt.Errorf("failed to read file: %s", err.Error())
@@ -201,7 +272,7 @@ func (t *Teams) processActivity(w http.ResponseWriter, req *http.Request) {
 			}
 
 			msg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(consentCtx.Command), "<at>BotKube</at>"))
-			e := execute.NewDefaultExecutor(msg, t.AllowKubectl, t.RestrictAccess, t.ClusterName, true)
+			e := t.executorFactory(msg, t.AllowKubectl, t.RestrictAccess, t.ClusterName, true)
 			out := e.Execute()
 
 			aj, _ := json.MarshalIndent(turn.Activity, "", "  ")
@@ -230,11 +301,25 @@ func (t *Teams) processActivity(w http.ResponseWriter, req *http.Request) {
 		},
 	})
 	if err != nil {
-		logging.Logger.Errorf("Failed to process request. %s", err.Error())
+		t.logger.Errorf("Failed to process request. %s", err.Error())
 	}
 }
 
-func (t *Teams) processMessage(activity schema.Activity) string {
+// commandResponse is the result of handling one incoming Teams message.
+type commandResponse struct {
+	// Text is the reply ready for plain-text delivery (already wrapped in a
+	// code block where appropriate).
+	Text string
+	// Command is the command that was executed to produce Output, empty
+	// unless Text came from running a command. Used to decide whether the
+	// reply should instead be rendered as an Adaptive Card table, and to
+	// build that card's follow-up actions.
+	Command string
+	// Output is Command's raw, unwrapped output.
+	Output string
+}
+
+func (t *Teams) processMessage(activity schema.Activity) commandResponse {
 	// Trim @BotKube prefix
 	msg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(activity.Text), "<at>BotKube</at>"))
 
@@ -247,13 +332,81 @@ func (t *Teams) processMessage(activity schema.Activity) string {
 			t.ConversationRef.ChannelID = ID.(string)
 			t.ConversationRef.Conversation.ID = ID.(string)
 		}
-		return "Okay. I'll send notifications to this channel"
+		return commandResponse{Text: "Okay. I'll send notifications to this channel"}
+	}
+
+	if resp, handled := t.handleFiltersCommand(msg); handled {
+		return commandResponse{Text: resp, Command: msg, Output: resp}
 	}
 
 	// Multicluster is not supported for Teams
-	e := execute.NewDefaultExecutor(msg, t.AllowKubectl, t.RestrictAccess, t.ClusterName, true)
+	e := t.executorFactory(msg, t.AllowKubectl, t.RestrictAccess, t.ClusterName, true)
+	out := e.Execute()
+	return commandResponse{
+		Text:    fmt.Sprintf("```%s```", out),
+		Command: msg,
+		Output:  out,
+	}
+}
+
+// handleFiltersCommand recognizes the "filters list/enable/disable" command
+// grammar and, if msg matches one of them, returns the response to send back
+// along with handled=true. Any other message returns handled=false so the
+// caller falls through to the normal command executor.
+func (t *Teams) handleFiltersCommand(msg string) (resp string, handled bool) {
+	switch {
+	case msg == filtersListCmd:
+		return formatFilterList(t.filterEngine.List()), true
+
+	case strings.HasPrefix(msg, filtersEnableCmd+" "):
+		name := strings.TrimSpace(strings.TrimPrefix(msg, filtersEnableCmd+" "))
+		if err := t.filterEngine.SetEnabled(name, true); err != nil {
+			return err.Error(), true
+		}
+		return fmt.Sprintf("Enabled filter %q", name), true
+
+	case strings.HasPrefix(msg, filtersDisableCmd+" "):
+		name := strings.TrimSpace(strings.TrimPrefix(msg, filtersDisableCmd+" "))
+		if err := t.filterEngine.SetEnabled(name, false); err != nil {
+			return err.Error(), true
+		}
+		return fmt.Sprintf("Disabled filter %q", name), true
+
+	default:
+		return "", false
+	}
+}
+
+func formatFilterList(infos []filterengine.FilterInfo) string {
+	var b strings.Builder
+	b.WriteString("FILTER\t\tENABLED\t\tDESCRIPTION\n")
+	for _, i := range infos {
+		fmt.Fprintf(&b, "%s\t\t%v\t\t%s\n", i.Name, i.Enabled, i.Description)
+	}
+	return b.String()
+}
+
+// handleActionSubmit runs the follow-up command carried by an Adaptive Card
+// Action.Submit button (e.g. "describe", "logs", "delete") and replies with
+// its output, the same way the file-consent flow re-runs its own command.
+func (t *Teams) handleActionSubmit(turn *coreActivity.TurnContext) (schema.Activity, error) {
+	if turn.Activity.Value["context"] == nil {
+		return schema.Activity{}, nil
+	}
+
+	actionCtx := ActionContext{}
+	ctxJSON, err := json.Marshal(turn.Activity.Value["context"])
+	if err != nil {
+		return schema.Activity{}, err
+	}
+	if err := json.Unmarshal(ctxJSON, &actionCtx); err != nil {
+		return schema.Activity{}, err
+	}
+
+	msg := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(actionCtx.Command), "<at>BotKube</at>"))
+	e := t.executorFactory(msg, t.AllowKubectl, t.RestrictAccess, t.ClusterName, true)
 	out := e.Execute()
-	return fmt.Sprintf("```%s```", out)
+	return turn.SendActivity(coreActivity.MsgOptionText(fmt.Sprintf("```%s```", out)))
 }
 
 func (t *Teams) pushProcessedConsent(ID string, ref schema.ConversationReference) {
@@ -291,22 +444,32 @@ func (t *Teams) putRequest(u string, data []byte) error {
 	return nil
 }
 
-func (t *Teams) SendEvent(event events.Event) error {
+// SendEvent sends event notification to MsTeams
+func (t *Teams) SendEvent(ctx context.Context, event events.Event) error {
+	filtered, err := t.filterEngine.Run(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to run filters on event: %w", err)
+	}
+	event, ok := filtered.(events.Event)
+	if !ok {
+		return fmt.Errorf("filter chain returned unexpected type %T", filtered)
+	}
+
 	card := formatTeamsMessage(event, t.NotifType)
-	if err := t.sendProactiveMessage(card); err != nil {
-		logging.Logger.Errorf("Failed to send notification. %s", err.Error())
+	if err := t.sendProactiveMessage(ctx, card); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
 	}
-	logging.Logger.Debugf("Event successfully sent to MS Teams >> %+v", event)
+	t.logger.Debugf("Event successfully sent to MS Teams >> %+v", event)
 	return nil
 }
 
 // SendMessage sends message to MsTeams
-func (t *Teams) SendMessage(msg string) error {
+func (t *Teams) SendMessage(ctx context.Context, msg string) error {
 	if t.ConversationRef == nil {
-		logging.Logger.Infof("Skipping SendMessage since conversation ref not set")
+		t.logger.Infof("Skipping SendMessage since conversation ref not set")
 		return nil
 	}
-	err := t.Adapter.ProactiveMessage(context.TODO(), *t.ConversationRef, coreActivity.HandlerFuncs{
+	err := t.Adapter.ProactiveMessage(ctx, *t.ConversationRef, coreActivity.HandlerFuncs{
 		OnMessageFunc: func(turn *coreActivity.TurnContext) (schema.Activity, error) {
 			return turn.SendActivity(coreActivity.MsgOptionText(msg))
 		},
@@ -314,16 +477,16 @@ func (t *Teams) SendMessage(msg string) error {
 	if err != nil {
 		return err
 	}
-	logging.Logger.Debug("Message successfully sent to MS Teams")
+	t.logger.Debug("Message successfully sent to MS Teams")
 	return nil
 }
 
-func (t *Teams) sendProactiveMessage(card map[string]interface{}) error {
+func (t *Teams) sendProactiveMessage(ctx context.Context, card map[string]interface{}) error {
 	if t.ConversationRef == nil {
-		logging.Logger.Infof("Skipping SendMessage since conversation ref not set")
+		t.logger.Infof("Skipping SendMessage since conversation ref not set")
 		return nil
 	}
-	err := t.Adapter.ProactiveMessage(context.TODO(), *t.ConversationRef, coreActivity.HandlerFuncs{
+	err := t.Adapter.ProactiveMessage(ctx, *t.ConversationRef, coreActivity.HandlerFuncs{
 		OnMessageFunc: func(turn *coreActivity.TurnContext) (schema.Activity, error) {
 			attachments := []schema.Attachment{
 				{
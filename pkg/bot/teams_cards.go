@@ -0,0 +1,185 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package bot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// actionSubmitType marks an Adaptive Card Action.Submit payload as a BotKube
+// follow-up command, distinguishing it from the existing file-consent
+// invoke payloads which use their own "type" values.
+const actionSubmitType = "actionSubmit"
+
+// ActionContext carries the follow-up command triggered by an Adaptive Card
+// Action.Submit button, the same way ConsentContext carries the command for
+// a pending file-consent upload.
+type ActionContext struct {
+	Command string
+}
+
+// followUpVerbs are offered as Action.Submit buttons below a structured
+// command's result card.
+var followUpVerbs = []string{"describe", "logs", "delete"}
+
+// structuredListCommands are BotKube's own commands whose output is already
+// a tabular list and so renders better as a card than as a code block.
+var structuredListCommands = map[string]bool{
+	"commands list": true,
+	"filters list":  true,
+}
+
+// isStructuredCommand reports whether cmd's output is suited to an Adaptive
+// Card rather than a plain code block: kubectl "-o json" output, or one of
+// BotKube's own tabular list commands.
+func isStructuredCommand(cmd string) bool {
+	return isJSONCommand(cmd) || structuredListCommands[cmd]
+}
+
+// isJSONCommand reports whether cmd asked kubectl for JSON output. JSON
+// lines have no tab characters to build a table row from and no resource
+// name isolated in a single cell, so tableRows renders them as one
+// formatted block instead of a row-targetable table.
+func isJSONCommand(cmd string) bool {
+	return strings.Contains(cmd, "-o json") || strings.Contains(cmd, "-o=json")
+}
+
+// buildAdaptiveCard renders a command's output as an Adaptive Card: a
+// header naming clusterName and a table built from out's rows. Rows listing
+// a known kubectl resource kind get follow-up Action.Submit buttons that run
+// describe/logs/delete against that specific row's resource.
+func buildAdaptiveCard(clusterName, cmd, out string) map[string]interface{} {
+	body := []interface{}{
+		map[string]interface{}{
+			"type":   "TextBlock",
+			"text":   fmt.Sprintf("Cluster: %s", clusterName),
+			"weight": "Bolder",
+			"size":   "Medium",
+		},
+	}
+	body = append(body, tableRows(cmd, out)...)
+
+	return map[string]interface{}{
+		"type":    "AdaptiveCard",
+		"version": "1.2",
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"body":    body,
+	}
+}
+
+// kubectlGetKind reports the resource kind listed by a "kubectl get <kind>"
+// command, so tableRows knows what each row's follow-up actions should
+// target. It returns ok=false for any other command shape, including
+// BotKube's own structuredListCommands, which list nothing kubectl can act
+// on.
+func kubectlGetKind(cmd string) (kind string, ok bool) {
+	fields := strings.Fields(cmd)
+	for i := 0; i < len(fields)-2; i++ {
+		if fields[i] == "kubectl" && fields[i+1] == "get" {
+			return fields[i+2], true
+		}
+	}
+	return "", false
+}
+
+// tableRows turns out's tab-separated lines into one ColumnSet per row. When
+// cmd is a "kubectl get <kind>" listing, every row but the header gets an
+// extra column of follow-up Action.Submit buttons targeting that row's
+// resource (its first cell). JSON command output and lines with no cells
+// fall back to a single monospaced TextBlock: JSON has no tab-separated
+// resource name to target a follow-up action at, and non-tabular output
+// still needs to render instead of being dropped.
+func tableRows(cmd, out string) []interface{} {
+	if isJSONCommand(cmd) {
+		return []interface{}{
+			map[string]interface{}{"type": "TextBlock", "text": out, "wrap": true, "fontType": "Monospace"},
+		}
+	}
+
+	kind, hasKind := kubectlGetKind(cmd)
+
+	var rows []interface{}
+	for i, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		cells := splitCells(line)
+		if len(cells) == 0 {
+			continue
+		}
+
+		columns := make([]interface{}, 0, len(cells)+1)
+		for _, cell := range cells {
+			columns = append(columns, map[string]interface{}{
+				"type":  "Column",
+				"width": "auto",
+				"items": []interface{}{
+					map[string]interface{}{"type": "TextBlock", "text": cell, "wrap": true},
+				},
+			})
+		}
+		if hasKind && i > 0 {
+			columns = append(columns, map[string]interface{}{
+				"type":  "Column",
+				"width": "auto",
+				"items": []interface{}{
+					map[string]interface{}{"type": "ActionSet", "actions": followUpActions(kind, cells[0])},
+				},
+			})
+		}
+
+		rows = append(rows, map[string]interface{}{"type": "ColumnSet", "columns": columns})
+	}
+	if len(rows) == 0 {
+		return []interface{}{
+			map[string]interface{}{"type": "TextBlock", "text": out, "wrap": true, "fontType": "Monospace"},
+		}
+	}
+	return rows
+}
+
+// splitCells splits a tab-separated line into its non-empty, trimmed cells.
+func splitCells(line string) []string {
+	var cells []string
+	for _, cell := range strings.Split(line, "\t") {
+		cell = strings.TrimSpace(cell)
+		if cell == "" {
+			continue
+		}
+		cells = append(cells, cell)
+	}
+	return cells
+}
+
+// followUpActions returns the Action.Submit buttons for a single row, one
+// per followUpVerbs, each running "kubectl <verb> <kind> <name>" against the
+// resource that row lists.
+func followUpActions(kind, name string) []interface{} {
+	actions := make([]interface{}, 0, len(followUpVerbs))
+	for _, verb := range followUpVerbs {
+		actions = append(actions, map[string]interface{}{
+			"type":  "Action.Submit",
+			"title": strings.Title(verb),
+			"data": map[string]interface{}{
+				"type":    actionSubmitType,
+				"context": ActionContext{Command: fmt.Sprintf("kubectl %s %s %s", verb, kind, name)},
+			},
+		})
+	}
+	return actions
+}
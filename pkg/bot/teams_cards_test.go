@@ -0,0 +1,170 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package bot
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsStructuredCommand(t *testing.T) {
+	type test struct {
+		cmd      string
+		expected bool
+	}
+
+	tests := []test{
+		{cmd: "kubectl get pods -o json", expected: true},
+		{cmd: "kubectl get pods -o=json", expected: true},
+		{cmd: "kubectl get pods", expected: false},
+		{cmd: "filters list", expected: true},
+		{cmd: "commands list", expected: true},
+		{cmd: "kubectl describe pod foo", expected: false},
+	}
+
+	for _, ts := range tests {
+		got := isStructuredCommand(ts.cmd)
+		if got != ts.expected {
+			t.Errorf("isStructuredCommand(%q) = %v, want %v", ts.cmd, got, ts.expected)
+		}
+	}
+}
+
+func TestKubectlGetKind(t *testing.T) {
+	type test struct {
+		cmd          string
+		expectedKind string
+		expectedOK   bool
+	}
+
+	tests := []test{
+		{cmd: "kubectl get pods", expectedKind: "pods", expectedOK: true},
+		{cmd: "kubectl get pods -o json", expectedKind: "pods", expectedOK: true},
+		{cmd: "kubectl describe pod foo", expectedOK: false},
+		{cmd: "filters list", expectedOK: false},
+		{cmd: "kubectl get", expectedOK: false},
+	}
+
+	for _, ts := range tests {
+		kind, ok := kubectlGetKind(ts.cmd)
+		if ok != ts.expectedOK || (ok && kind != ts.expectedKind) {
+			t.Errorf("kubectlGetKind(%q) = (%q, %v), want (%q, %v)", ts.cmd, kind, ok, ts.expectedKind, ts.expectedOK)
+		}
+	}
+}
+
+// TestTableRowsFollowUpActionsTargetRow guards against follow-up buttons
+// submitting the whole original command instead of a command targeted at
+// the specific resource listed by their row.
+func TestTableRowsFollowUpActionsTargetRow(t *testing.T) {
+	out := "NAME\tREADY\nfoo-pod\t1/1\nbar-pod\t1/1"
+	rows := tableRows("kubectl get pods", out)
+	if len(rows) != 3 {
+		t.Fatalf("tableRows returned %d rows, want 3 (1 header + 2 data)", len(rows))
+	}
+
+	dataRow, ok := rows[1].(map[string]interface{})
+	if !ok {
+		t.Fatalf("rows[1] is not a ColumnSet map: %#v", rows[1])
+	}
+	columns, ok := dataRow["columns"].([]interface{})
+	if !ok {
+		t.Fatalf("rows[1] has no columns: %#v", dataRow)
+	}
+
+	lastColumn, ok := columns[len(columns)-1].(map[string]interface{})
+	if !ok {
+		t.Fatalf("last column is not a map: %#v", columns[len(columns)-1])
+	}
+	items, ok := lastColumn["items"].([]interface{})
+	if !ok || len(items) != 1 {
+		t.Fatalf("last column has unexpected items: %#v", lastColumn)
+	}
+	actionSet, ok := items[0].(map[string]interface{})
+	if !ok || actionSet["type"] != "ActionSet" {
+		t.Fatalf("last column item is not an ActionSet: %#v", items[0])
+	}
+	actions, ok := actionSet["actions"].([]interface{})
+	if !ok || len(actions) != len(followUpVerbs) {
+		t.Fatalf("ActionSet has %d actions, want %d", len(actions), len(followUpVerbs))
+	}
+
+	action, ok := actions[0].(map[string]interface{})
+	if !ok {
+		t.Fatalf("action is not a map: %#v", actions[0])
+	}
+	data, ok := action["data"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("action has no data: %#v", action)
+	}
+	ctx, ok := data["context"].(ActionContext)
+	if !ok {
+		t.Fatalf("action data has no ActionContext: %#v", data)
+	}
+	if !strings.Contains(ctx.Command, "foo-pod") {
+		t.Errorf("follow-up command = %q, want it to target row resource foo-pod", ctx.Command)
+	}
+	if strings.Contains(ctx.Command, "bar-pod") {
+		t.Errorf("follow-up command = %q, leaked the other row's resource", ctx.Command)
+	}
+}
+
+// TestTableRowsNoActionsWithoutKnownKind guards against attaching follow-up
+// buttons to commands tableRows can't derive a runnable target for.
+func TestTableRowsNoActionsWithoutKnownKind(t *testing.T) {
+	rows := tableRows("filters list", "FILTER\tENABLED\nNamespaceChecker\ttrue")
+	if len(rows) != 2 {
+		t.Fatalf("tableRows returned %d rows, want 2 (1 header + 1 data)", len(rows))
+	}
+	row, ok := rows[1].(map[string]interface{})
+	if !ok {
+		t.Fatalf("rows[1] is not a ColumnSet map: %#v", rows[1])
+	}
+	columns, ok := row["columns"].([]interface{})
+	if !ok {
+		t.Fatalf("rows[1] has no columns: %#v", row)
+	}
+	if len(columns) != 2 {
+		t.Errorf("got %d columns, want 2 (no follow-up action column appended)", len(columns))
+	}
+}
+
+// TestTableRowsJSONCommandRendersAsSingleBlock guards against splitting
+// JSON kubectl output on tabs (it has none, so every line would become its
+// own single-cell "row") and against attaching a follow-up action whose
+// "resource name" is really a raw JSON line like `"apiVersion": "v1",`.
+func TestTableRowsJSONCommandRendersAsSingleBlock(t *testing.T) {
+	out := "{\n  \"apiVersion\": \"v1\",\n  \"kind\": \"Pod\"\n}"
+	rows := tableRows("kubectl get pods -o json", out)
+	if len(rows) != 1 {
+		t.Fatalf("tableRows returned %d rows for JSON output, want exactly 1 block", len(rows))
+	}
+
+	block, ok := rows[0].(map[string]interface{})
+	if !ok || block["type"] != "TextBlock" {
+		t.Fatalf("rows[0] is not a TextBlock: %#v", rows[0])
+	}
+	if block["text"] != out {
+		t.Errorf("TextBlock text = %v, want the untouched JSON output", block["text"])
+	}
+	if _, hasColumns := block["columns"]; hasColumns {
+		t.Error("JSON output rendered as a ColumnSet, want a plain TextBlock with no row-targeted actions")
+	}
+}
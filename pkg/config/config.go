@@ -0,0 +1,149 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/kelseyhightower/envconfig"
+	"gopkg.in/yaml.v2"
+)
+
+var validate = validator.New()
+
+// configEnvVarPath names the environment variable holding the location of
+// the BotKube YAML configuration file; when it is unset, defaultConfigPath
+// is used.
+const configEnvVarPath = "BOTKUBE_CONFIG_PATH"
+
+const defaultConfigPath = "/config/config.yaml"
+
+// NotifType defines the notification style used when sending events to a backend.
+type NotifType string
+
+const (
+	// ShortNotify renders a compact, single-line summary of an event.
+	ShortNotify NotifType = "short"
+	// LongNotify renders the full detail of an event.
+	LongNotify NotifType = "long"
+)
+
+// Settings holds the global toggles that apply across every communication backend.
+type Settings struct {
+	ClusterName    string `yaml:"clustername" envconfig:"CLUSTER_NAME"`
+	AllowKubectl   bool   `yaml:"allowkubectl" envconfig:"ALLOW_KUBECTL"`
+	RestrictAccess bool   `yaml:"restrictaccess" envconfig:"RESTRICT_ACCESS"`
+}
+
+// Communications groups the configuration for every chat backend BotKube can start.
+type Communications struct {
+	Teams   Teams   `yaml:"teams"`
+	Discord Discord `yaml:"discord"`
+}
+
+// Discord holds the credentials and toggles needed to start the Discord backend.
+type Discord struct {
+	Enabled   bool   `yaml:"enabled" envconfig:"DISCORD_ENABLED"`
+	Token     string `yaml:"token" envconfig:"DISCORD_BOT_TOKEN" validate:"required_if=Enabled true"`
+	BotID     string `yaml:"botid" envconfig:"DISCORD_BOT_ID" validate:"required_if=Enabled true"`
+	GuildID   string `yaml:"guildid" envconfig:"DISCORD_GUILD_ID"`
+	ChannelID string `yaml:"channelid" envconfig:"DISCORD_CHANNEL_ID"`
+}
+
+// Teams holds the credentials and toggles needed to start the Teams backend.
+// Fields can be overridden by environment variables (prefixed with TEAMS_) so
+// that Helm deployments can inject credentials as Secrets rather than baking
+// them into the config file.
+type Teams struct {
+	Enabled     bool      `yaml:"enabled" envconfig:"TEAMS_ENABLED"`
+	AppID       string    `yaml:"appid" envconfig:"TEAMS_APPID" validate:"required_if=Enabled true"`
+	BotPassword string    `yaml:"botpassword" envconfig:"TEAMS_BOTPASSWORD" validate:"required_if=Enabled true"`
+	MessagePath string    `yaml:"messagepath" envconfig:"TEAMS_MESSAGEPATH"`
+	Port        string    `yaml:"port" envconfig:"TEAMS_PORT"`
+	NotifType   NotifType `yaml:"notiftype"`
+}
+
+// CurrentConfigVersion is the schema version produced by this build. It is
+// bumped whenever a migration is added to pkg/config/migrate, so older
+// exported configs can be detected and brought up to date.
+const CurrentConfigVersion = 2
+
+// Config is the root BotKube configuration, loaded from a YAML file and then
+// overlaid with any matching environment variables.
+type Config struct {
+	ConfigVersion  int            `yaml:"configVersion"`
+	Settings       Settings       `yaml:"settings"`
+	Communications Communications `yaml:"communications"`
+}
+
+// ParseStrict unmarshals a config document the same way New does, but fails
+// if the document contains keys that don't map onto the Config struct. It is
+// used by tooling (such as the config exporter) that must not silently
+// accept a config written against an unknown schema.
+func ParseStrict(data []byte) (*Config, error) {
+	c := &Config{}
+	if err := yaml.UnmarshalStrict(data, c); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return c, nil
+}
+
+// Validate checks c against its `validate` struct tags, e.g. that a backend
+// marked Enabled also has the credentials it needs to start.
+func (c *Config) Validate() error {
+	if err := validate.Struct(c); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
+// New reads the BotKube configuration from the YAML file pointed to by
+// BOTKUBE_CONFIG_PATH (or defaultConfigPath if unset), then overrides any
+// matching fields from the environment via envconfig.
+func New() (*Config, error) {
+	path := os.Getenv(configEnvVarPath)
+	if path == "" {
+		path = defaultConfigPath
+	}
+
+	c := &Config{}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config file %s: %w", path, err)
+	}
+
+	if err := envconfig.Process("", &c.Settings); err != nil {
+		return nil, fmt.Errorf("failed to process settings env vars: %w", err)
+	}
+	if err := envconfig.Process("", &c.Communications.Teams); err != nil {
+		return nil, fmt.Errorf("failed to process teams env vars: %w", err)
+	}
+	if err := envconfig.Process("", &c.Communications.Discord); err != nil {
+		return nil, fmt.Errorf("failed to process discord env vars: %w", err)
+	}
+
+	return c, nil
+}
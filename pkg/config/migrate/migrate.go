@@ -0,0 +1,82 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package migrate applies versioned transformations to a raw BotKube config
+// document so that configs exported from older BotKube installations can be
+// brought up to the schema the running version expects.
+package migrate
+
+import "fmt"
+
+// Migration transforms a raw config document from schema version From to
+// schema version To. It operates on the untyped YAML tree rather than
+// config.Config so that renamed or restructured fields can be migrated
+// without that struct ever having to know its own history.
+type Migration struct {
+	From  int
+	To    int
+	Apply func(doc map[string]interface{}) error
+}
+
+var registry []Migration
+
+// Register adds a migration to the set applied by Run. Migrations are
+// expected to be registered in increasing order of From via package init()
+// functions.
+func Register(m Migration) {
+	registry = append(registry, m)
+}
+
+// Run walks doc forward from its current configVersion (0 if unset) to
+// targetVersion, applying each registered migration in turn and stamping
+// doc's configVersion after every step. It fails if no migration is
+// registered for a version encountered along the way.
+func Run(doc map[string]interface{}, targetVersion int) error {
+	version := currentVersion(doc)
+	for version < targetVersion {
+		m, ok := find(version)
+		if !ok {
+			return fmt.Errorf("no migration registered from configVersion %d", version)
+		}
+		if err := m.Apply(doc); err != nil {
+			return fmt.Errorf("migration from %d to %d failed: %w", m.From, m.To, err)
+		}
+		doc["configVersion"] = m.To
+		version = m.To
+	}
+	return nil
+}
+
+func currentVersion(doc map[string]interface{}) int {
+	switch v := doc["configVersion"].(type) {
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+func find(from int) (Migration, bool) {
+	for _, m := range registry {
+		if m.From == from {
+			return m, true
+		}
+	}
+	return Migration{}, false
+}
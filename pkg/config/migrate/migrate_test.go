@@ -0,0 +1,78 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package migrate
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// TestRunOnRealYAMLDocument guards against SubMap assuming a
+// map[string]interface{} shape: yaml.Unmarshal decodes nested mappings into
+// an interface{} as map[interface{}]interface{}, not map[string]interface{}.
+func TestRunOnRealYAMLDocument(t *testing.T) {
+	raw := `
+communications:
+  teams:
+    apppassword: s3cr3t
+settings:
+  clustername: prod
+`
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal([]byte(raw), &doc); err != nil {
+		t.Fatalf("failed to unmarshal fixture: %v", err)
+	}
+
+	if err := Run(doc, 2); err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+
+	teams := SubMap(SubMap(doc, "communications"), "teams")
+	if teams == nil {
+		t.Fatal("communications.teams missing after migration")
+	}
+	if _, ok := teams["apppassword"]; ok {
+		t.Error("apppassword was not removed")
+	}
+	if pw, ok := teams["botpassword"]; !ok || pw != "s3cr3t" {
+		t.Errorf("botpassword = %v, %v; want s3cr3t, true", pw, ok)
+	}
+
+	settings := SubMap(doc, "settings")
+	if settings == nil {
+		t.Fatal("settings missing after migration")
+	}
+	if _, ok := settings["clustername"]; ok {
+		t.Error("clustername was not removed from settings")
+	}
+	sources, ok := settings["sources"].([]interface{})
+	if !ok || len(sources) != 1 {
+		t.Fatalf("settings.sources = %#v; want one entry", settings["sources"])
+	}
+	source, ok := sources[0].(map[string]interface{})
+	if !ok || source["clustername"] != "prod" {
+		t.Errorf("settings.sources[0] = %#v; want clustername=prod", sources[0])
+	}
+
+	if doc["configVersion"] != 2 {
+		t.Errorf("configVersion = %v; want 2", doc["configVersion"])
+	}
+}
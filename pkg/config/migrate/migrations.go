@@ -0,0 +1,88 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package migrate
+
+func init() {
+	Register(Migration{From: 0, To: 1, Apply: renameTeamsAppPassword})
+	Register(Migration{From: 1, To: 2, Apply: splitClusterNameIntoSourceOverrides})
+}
+
+// renameTeamsAppPassword renames communications.teams.apppassword to
+// communications.teams.botpassword, matching the field's current name.
+func renameTeamsAppPassword(doc map[string]interface{}) error {
+	teams := SubMap(SubMap(doc, "communications"), "teams")
+	if teams == nil {
+		return nil
+	}
+	if pw, ok := teams["apppassword"]; ok {
+		teams["botpassword"] = pw
+		delete(teams, "apppassword")
+	}
+	return nil
+}
+
+// splitClusterNameIntoSourceOverrides moves the single global
+// settings.clustername into a per-source override list, so individual
+// notification sources can later report against different cluster names.
+func splitClusterNameIntoSourceOverrides(doc map[string]interface{}) error {
+	settings := SubMap(doc, "settings")
+	if settings == nil {
+		return nil
+	}
+	clusterName, ok := settings["clustername"]
+	if !ok {
+		return nil
+	}
+	settings["sources"] = []interface{}{
+		map[string]interface{}{"name": "default", "clustername": clusterName},
+	}
+	delete(settings, "clustername")
+	return nil
+}
+
+// SubMap returns doc[key] as a map[string]interface{}, or nil if it is
+// missing or of an unexpected type. yaml.Unmarshal decodes nested mapping
+// nodes as map[interface{}]interface{} rather than map[string]interface{},
+// so that shape is converted and written back into doc so mutations the
+// caller makes to the returned map are visible in doc too. Exported so other
+// packages that walk a raw config document (e.g. cmd/config-exporter) don't
+// have to duplicate this conversion.
+func SubMap(doc map[string]interface{}, key string) map[string]interface{} {
+	if doc == nil {
+		return nil
+	}
+	switch v := doc[key].(type) {
+	case map[string]interface{}:
+		return v
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			ks, ok := k.(string)
+			if !ok {
+				continue
+			}
+			converted[ks] = val
+		}
+		doc[key] = converted
+		return converted
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package filterengine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Persister stores a filter engine's enabled/disabled state somewhere
+// durable, so it survives a bot restart.
+type Persister interface {
+	Persist(ctx context.Context, state map[string]bool) error
+}
+
+// ConfigMapPersister persists filter state as string "true"/"false" values
+// in a single Kubernetes ConfigMap, creating it on first use.
+type ConfigMapPersister struct {
+	client    kubernetes.Interface
+	namespace string
+	name      string
+}
+
+// NewConfigMapPersister returns a Persister backed by the named ConfigMap.
+func NewConfigMapPersister(client kubernetes.Interface, namespace, name string) *ConfigMapPersister {
+	return &ConfigMapPersister{client: client, namespace: namespace, name: name}
+}
+
+// Persist writes state to the ConfigMap, creating it if it does not exist yet.
+func (p *ConfigMapPersister) Persist(ctx context.Context, state map[string]bool) error {
+	data := make(map[string]string, len(state))
+	for name, enabled := range state {
+		data[name] = strconv.FormatBool(enabled)
+	}
+
+	cms := p.client.CoreV1().ConfigMaps(p.namespace)
+	cm, err := cms.Get(ctx, p.name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = cms.Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: p.name, Namespace: p.namespace},
+			Data:       data,
+		}, metav1.CreateOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create filter state configmap: %w", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get filter state configmap: %w", err)
+	}
+
+	cm.Data = data
+	if _, err := cms.Update(ctx, cm, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update filter state configmap: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+// Package filterengine runs a pluggable chain of Filters over event or
+// command output. Filters live in an Engine built by New and injected into
+// the bots that use it, not in a package-level filter list, so filters can
+// be enabled, disabled and tested in isolation per Engine. Bots given the
+// same Engine share its filters and their enabled state.
+package filterengine
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Filter transforms in (an events.Event or raw command output, depending on
+// where the engine is wired in) and returns the transformed value. It is
+// handed the Kubernetes client and a logger scoped to its own name instead
+// of reaching for package-level globals.
+type Filter interface {
+	// Name uniquely identifies the filter, used to enable/disable it and
+	// in the ConfigMap it persists its state to.
+	Name() string
+	// Describe returns a one-line, human readable description shown by
+	// the "filters list" command.
+	Describe() string
+	Run(ctx context.Context, client kubernetes.Interface, logger *zap.SugaredLogger, in interface{}) (interface{}, error)
+}
+
+// FilterInfo is the read-only view of a registered filter's state returned by List.
+type FilterInfo struct {
+	Name        string
+	Description string
+	Enabled     bool
+}
+
+// Engine runs a chain of registered Filters over incoming values and tracks
+// which of them are currently enabled.
+type Engine interface {
+	Register(f Filter)
+	SetEnabled(name string, enabled bool) error
+	List() []FilterInfo
+	Run(ctx context.Context, in interface{}) (interface{}, error)
+}
+
+type registeredFilter struct {
+	filter  Filter
+	enabled bool
+}
+
+// DefaultEngine is Engine's default, in-memory implementation. Enabled state
+// changes are optionally persisted via a Persister.
+type DefaultEngine struct {
+	mu        sync.RWMutex
+	filters   []*registeredFilter
+	client    kubernetes.Interface
+	logger    *zap.SugaredLogger
+	persister Persister
+}
+
+// New returns an Engine that runs filters against client, logging through a
+// logger scoped per-filter. Pass a nil Persister to keep enabled state
+// in-memory only.
+func New(client kubernetes.Interface, logger *zap.Logger, persister Persister) *DefaultEngine {
+	return &DefaultEngine{
+		client:    client,
+		logger:    logger.Sugar(),
+		persister: persister,
+	}
+}
+
+// Register adds f to the engine, enabled by default.
+func (e *DefaultEngine) Register(f Filter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.filters = append(e.filters, &registeredFilter{filter: f, enabled: true})
+}
+
+// SetEnabled toggles the named filter on or off and persists the change if
+// the engine was constructed with a Persister.
+func (e *DefaultEngine) SetEnabled(name string, enabled bool) error {
+	e.mu.Lock()
+	rf := e.find(name)
+	if rf == nil {
+		e.mu.Unlock()
+		return fmt.Errorf("no such filter: %s", name)
+	}
+	rf.enabled = enabled
+	state := e.stateLocked()
+	e.mu.Unlock()
+
+	if e.persister == nil {
+		return nil
+	}
+	if err := e.persister.Persist(context.Background(), state); err != nil {
+		return fmt.Errorf("failed to persist filter state: %w", err)
+	}
+	return nil
+}
+
+// List returns the current name/enabled state of every registered filter.
+func (e *DefaultEngine) List() []FilterInfo {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	infos := make([]FilterInfo, 0, len(e.filters))
+	for _, rf := range e.filters {
+		infos = append(infos, FilterInfo{
+			Name:        rf.filter.Name(),
+			Description: rf.filter.Describe(),
+			Enabled:     rf.enabled,
+		})
+	}
+	return infos
+}
+
+// Run passes in through every enabled filter in registration order,
+// returning the final result. A disabled filter is skipped entirely.
+func (e *DefaultEngine) Run(ctx context.Context, in interface{}) (interface{}, error) {
+	e.mu.RLock()
+	filters := make([]*registeredFilter, len(e.filters))
+	copy(filters, e.filters)
+	e.mu.RUnlock()
+
+	out := in
+	for _, rf := range filters {
+		if !rf.enabled {
+			continue
+		}
+		var err error
+		out, err = rf.filter.Run(ctx, e.client, e.logger.Named(rf.filter.Name()), out)
+		if err != nil {
+			return nil, fmt.Errorf("filter %s failed: %w", rf.filter.Name(), err)
+		}
+	}
+	return out, nil
+}
+
+func (e *DefaultEngine) find(name string) *registeredFilter {
+	for _, rf := range e.filters {
+		if rf.filter.Name() == name {
+			return rf
+		}
+	}
+	return nil
+}
+
+func (e *DefaultEngine) stateLocked() map[string]bool {
+	state := make(map[string]bool, len(e.filters))
+	for _, rf := range e.filters {
+		state[rf.filter.Name()] = rf.enabled
+	}
+	return state
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2020 InfraCloud Technologies
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy of
+// this software and associated documentation files (the "Software"), to deal in
+// the Software without restriction, including without limitation the rights to
+// use, copy, modify, merge, publish, distribute, sublicense, and/or sell copies of
+// the Software, and to permit persons to whom the Software is furnished to do so,
+// subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in all
+// copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY, FITNESS
+// FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE AUTHORS OR
+// COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER LIABILITY, WHETHER
+// IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM, OUT OF OR IN
+// CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
+
+package filterengine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// upperFilter appends "-ran" to a string passed through it, and records
+// whether it ran so tests can assert Run actually skips disabled filters.
+type upperFilter struct {
+	name string
+	ran  *bool
+}
+
+func (f upperFilter) Name() string     { return f.name }
+func (f upperFilter) Describe() string { return "appends -ran to its input" }
+
+func (f upperFilter) Run(_ context.Context, _ kubernetes.Interface, _ *zap.SugaredLogger, in interface{}) (interface{}, error) {
+	*f.ran = true
+	s, ok := in.(string)
+	if !ok {
+		return in, nil
+	}
+	return fmt.Sprintf("%s-ran", s), nil
+}
+
+func newTestEngine() *DefaultEngine {
+	return New(fake.NewSimpleClientset(), zap.NewNop(), nil)
+}
+
+func TestEngineRunSkipsDisabledFilters(t *testing.T) {
+	e := newTestEngine()
+	var ran bool
+	e.Register(upperFilter{name: "f1", ran: &ran})
+
+	if err := e.SetEnabled("f1", false); err != nil {
+		t.Fatalf("SetEnabled returned error: %v", err)
+	}
+
+	out, err := e.Run(context.Background(), "in")
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if out != "in" {
+		t.Errorf("Run() = %v; want unchanged input since the only filter is disabled", out)
+	}
+	if ran {
+		t.Error("disabled filter ran")
+	}
+}
+
+func TestEngineRunAppliesEnabledFiltersInOrder(t *testing.T) {
+	e := newTestEngine()
+	var ran1, ran2 bool
+	e.Register(upperFilter{name: "f1", ran: &ran1})
+	e.Register(upperFilter{name: "f2", ran: &ran2})
+
+	out, err := e.Run(context.Background(), "in")
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	if out != "in-ran-ran" {
+		t.Errorf("Run() = %v; want both filters applied in registration order", out)
+	}
+	if !ran1 || !ran2 {
+		t.Error("expected both filters to run")
+	}
+}
+
+func TestSetEnabledUnknownFilter(t *testing.T) {
+	e := newTestEngine()
+	if err := e.SetEnabled("missing", true); err == nil {
+		t.Error("expected an error for an unregistered filter name")
+	}
+}
+
+func TestList(t *testing.T) {
+	e := newTestEngine()
+	var ran bool
+	e.Register(upperFilter{name: "f1", ran: &ran})
+
+	infos := e.List()
+	if len(infos) != 1 {
+		t.Fatalf("List() returned %d entries; want 1", len(infos))
+	}
+	if infos[0].Name != "f1" || !infos[0].Enabled {
+		t.Errorf("List()[0] = %+v; want Name=f1, Enabled=true", infos[0])
+	}
+}